@@ -1,8 +1,9 @@
 package gothreadsafebuffer
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -18,16 +19,25 @@ type ThreadSafeBuffer struct {
 	readLock *sync.Mutex
 	ready    chan struct{}
 
+	writeLock *sync.Mutex
+
 	config Config
 
-	notify *gonotify.Notify
+	notify      *gonotify.Notify
+	writeNotify *gonotify.Notify
 
 	bufferLock    *sync.Mutex
-	buffer        *bytes.Buffer
+	buffer        *segmentedBuffer
 	bufferSize    uint64
 	maxBufferSize uint64
+	peekBuf       []byte
 
 	drainTime time.Time
+
+	readDeadline  time.Time
+	writeDeadline time.Time
+	readTimer     *time.Timer
+	writeTimer    *time.Timer
 }
 
 // Create a new thread safe buffer
@@ -42,12 +52,15 @@ func NewThreadSafeBuffer(config Config) *ThreadSafeBuffer {
 		readLock: new(sync.Mutex),
 		ready:    make(chan struct{}),
 
+		writeLock: new(sync.Mutex),
+
 		config: config,
 
-		notify: gonotify.New(),
+		notify:      gonotify.New(),
+		writeNotify: gonotify.New(),
 
 		bufferLock: new(sync.Mutex),
-		buffer:     new(bytes.Buffer),
+		buffer:     newSegmentedBuffer(config.ChunkSize, config.BufferPool),
 	}
 
 	return threadSafeBuffer
@@ -55,18 +68,112 @@ func NewThreadSafeBuffer(config Config) *ThreadSafeBuffer {
 
 // Write is used to add data into the buffer and record the size of the buffer.
 func (tsb *ThreadSafeBuffer) Write(b []byte) (int, error) {
+	return tsb.WriteContext(context.Background(), b)
+}
+
+// WriteContext behaves like Write, but also returns ctx.Err() wrapped in a
+// BuffErr if ctx is canceled before the write completes. When
+// Config.BlockOnFull is set, a write that would exceed MaxBufferSize blocks
+// here (instead of erroring) until Read frees up enough space, chunking the
+// input as space becomes available.
+func (tsb *ThreadSafeBuffer) WriteContext(ctx context.Context, b []byte) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, &BuffErr{Op: "write", Err: ctx.Err()}
+	default:
+	}
+
+	if !tsb.config.BlockOnFull {
+		return tsb.writeChunk(b)
+	}
+
+	// serialize blocking writers so they take turns claiming free space
+	tsb.writeLock.Lock()
+	defer tsb.writeLock.Unlock()
+
+	var tickerC <-chan time.Time
+	if tsb.config.WriteTimeout != 0 {
+		ticker := time.NewTicker(tsb.config.WriteTimeout)
+		defer ticker.Stop()
+		tickerC = ticker.C
+	}
+
+	written := 0
+	for written < len(b) {
+		n, err := tsb.writeChunk(b[written:])
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		if n > 0 {
+			continue
+		}
+
+		// the buffer is full, wait for a Read to free up space or for the
+		// write to be unblocked by a deadline, context cancellation, timeout,
+		// or Close
+		select {
+		case <-ctx.Done():
+			return written, &BuffErr{Op: "write", Err: ctx.Err()}
+		case <-tsb.done:
+			return written, &BuffErr{Op: "write", Err: fmt.Errorf("%w", ErrClosed)}
+		case <-tickerC:
+			return written, &BuffErr{Op: "write", Err: fmt.Errorf("Failed to write in time")}
+		case _, ok := <-tsb.writeNotify.Ready():
+			if !ok {
+				return written, &BuffErr{Op: "write", Err: fmt.Errorf("%w", ErrClosed)}
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// writeChunk writes as much of b as currently fits in the buffer. When
+// Config.BlockOnFull is false this matches the original all-or-nothing
+// behavior: the whole write is rejected if it would exceed MaxBufferSize.
+// When Config.BlockOnFull is true and the buffer is full, it returns (0,
+// nil) so the caller knows to wait for space instead of erroring.
+func (tsb *ThreadSafeBuffer) writeChunk(b []byte) (int, error) {
 	tsb.bufferLock.Lock()
 	defer tsb.bufferLock.Unlock()
 
+	if !tsb.writeDeadline.IsZero() && !time.Now().Before(tsb.writeDeadline) {
+		return 0, &BuffErr{Op: "write", Err: fmt.Errorf("%w", os.ErrDeadlineExceeded)}
+	}
+
 	select {
 	case <-tsb.done:
-		return 0, &BuffErr{Op: "write", Err: fmt.Errorf("Thread safe buffer is closed")}
+		return 0, &BuffErr{Op: "write", Err: fmt.Errorf("%w", ErrClosed)}
 	default:
-		if tsb.config.MaxBuffer && tsb.buffer.Len()+len(b) > tsb.config.MaxBufferSize {
-			return 0, &BuffErr{Op: "write", Err: fmt.Errorf("write exceeds max buffer size")}
+		if !tsb.config.MaxBuffer {
+			n, err := tsb.buffer.Write(b)
+			tsb.notify.Add()
+			return n, err
+		}
+
+		if !tsb.config.BlockOnFull {
+			if tsb.buffer.Len()+len(b) > tsb.config.MaxBufferSize {
+				return 0, &BuffErr{Op: "write", Err: fmt.Errorf("write exceeds max buffer size")}
+			}
+
+			n, err := tsb.buffer.Write(b)
+			tsb.notify.Add()
+			return n, err
+		}
+
+		available := tsb.config.MaxBufferSize - tsb.buffer.Len()
+		if available <= 0 {
+			return 0, nil
+		}
+
+		toWrite := b
+		if len(toWrite) > available {
+			toWrite = toWrite[:available]
 		}
 
-		n, err := tsb.buffer.Write(b)
+		n, err := tsb.buffer.Write(toWrite)
 		tsb.notify.Add()
 		return n, err
 	}
@@ -76,6 +183,13 @@ func (tsb *ThreadSafeBuffer) Write(b []byte) (int, error) {
 // is enough data to be read the len(b), or the buffer is told to close
 // and we reached out drain timeout
 func (tsb *ThreadSafeBuffer) Read(b []byte) (int, error) {
+	return tsb.ReadContext(context.Background(), b)
+}
+
+// ReadContext behaves like Read, but also returns ctx.Err() wrapped in a
+// BuffErr as soon as ctx is canceled, instead of requiring the whole buffer
+// to be closed to unblock a pending Read.
+func (tsb *ThreadSafeBuffer) ReadContext(ctx context.Context, b []byte) (int, error) {
 	tsb.readLock.Lock()
 	defer tsb.readLock.Unlock()
 
@@ -85,6 +199,8 @@ func (tsb *ThreadSafeBuffer) Read(b []byte) (int, error) {
 
 		for {
 			select {
+			case <-ctx.Done():
+				return 0, &BuffErr{Op: "read", Err: ctx.Err()}
 			case <-ticker.C:
 				return 0, &BuffErr{Op: "read", Err: fmt.Errorf("Failed to read in time")}
 			case _, ok := <-tsb.notify.Ready():
@@ -101,6 +217,8 @@ func (tsb *ThreadSafeBuffer) Read(b []byte) (int, error) {
 	} else {
 		for {
 			select {
+			case <-ctx.Done():
+				return 0, &BuffErr{Op: "read", Err: ctx.Err()}
 			case _, ok := <-tsb.notify.Ready():
 				n, err := tsb.readLoop(b, !ok)
 				if err != nil {
@@ -120,6 +238,10 @@ func (tsb *ThreadSafeBuffer) readLoop(b []byte, draining bool) (int, error) {
 	tsb.bufferLock.Lock()
 	defer tsb.bufferLock.Unlock()
 
+	if !tsb.readDeadline.IsZero() && !time.Now().Before(tsb.readDeadline) {
+		return 0, &BuffErr{Op: "read", Err: fmt.Errorf("%w", os.ErrDeadlineExceeded)}
+	}
+
 	// we are closing the buffer
 	select {
 	case <-tsb.done:
@@ -134,13 +256,17 @@ func (tsb *ThreadSafeBuffer) readLoop(b []byte, draining bool) (int, error) {
 					tsb.notify.Add()
 				}
 
+				// no need to wake writers blocked on a full buffer here: the
+				// buffer is closing, so their select is already unblocked by
+				// <-tsb.done
+
 				return n, err
 			} else {
-				return 0, &BuffErr{Op: "read", Err: fmt.Errorf("Thread safe buffer is closed. Attempting to read more data than is in the buffer")}
+				return 0, &BuffErr{Op: "read", Err: fmt.Errorf("%w. Attempting to read more data than is in the buffer", ErrClosed)}
 			}
 		}
 
-		return 0, &BuffErr{Op: "read", Err: fmt.Errorf("Thread safe buffer is closed")}
+		return 0, &BuffErr{Op: "read", Err: fmt.Errorf("%w", ErrClosed)}
 	default:
 		// wait untill the buffer is full so we can read from it
 		if tsb.buffer.Len() >= len(b) {
@@ -151,6 +277,9 @@ func (tsb *ThreadSafeBuffer) readLoop(b []byte, draining bool) (int, error) {
 				tsb.notify.Add()
 			}
 
+			// space just freed up, wake any writers blocked on a full buffer
+			tsb.writeNotify.Add()
+
 			return n, err
 		}
 
@@ -158,6 +287,156 @@ func (tsb *ThreadSafeBuffer) readLoop(b []byte, draining bool) (int, error) {
 	}
 }
 
+// Peek returns the next n buffered bytes without consuming them, blocking
+// until n bytes are available or the buffer is closed (and, if configured,
+// drained). The returned []byte is only valid until the next Read or Peek
+// call.
+func (tsb *ThreadSafeBuffer) Peek(n int) ([]byte, error) {
+	return tsb.PeekContext(context.Background(), n)
+}
+
+// PeekContext behaves like Peek, but also returns ctx.Err() wrapped in a
+// BuffErr if ctx is canceled before n bytes become available.
+func (tsb *ThreadSafeBuffer) PeekContext(ctx context.Context, n int) ([]byte, error) {
+	tsb.readLock.Lock()
+	defer tsb.readLock.Unlock()
+
+	if n <= 0 {
+		return []byte{}, nil
+	}
+
+	if tsb.config.ReadTimeout != 0 {
+		ticker := time.NewTicker(tsb.config.ReadTimeout)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, &BuffErr{Op: "peek", Err: ctx.Err()}
+			case <-ticker.C:
+				return nil, &BuffErr{Op: "peek", Err: fmt.Errorf("Failed to read in time")}
+			case _, ok := <-tsb.notify.Ready():
+				b, err := tsb.peekLoop(n, !ok)
+				if err != nil {
+					return nil, err
+				}
+
+				if b != nil {
+					return b, nil
+				}
+			}
+		}
+	} else {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, &BuffErr{Op: "peek", Err: ctx.Err()}
+			case _, ok := <-tsb.notify.Ready():
+				b, err := tsb.peekLoop(n, !ok)
+				if err != nil {
+					return nil, err
+				}
+
+				if b != nil {
+					return b, nil
+				}
+			}
+		}
+	}
+}
+
+// function to loop over waiting for n bytes to be available in the buffer
+// to peek at, or an error
+func (tsb *ThreadSafeBuffer) peekLoop(n int, draining bool) ([]byte, error) {
+	tsb.bufferLock.Lock()
+	defer tsb.bufferLock.Unlock()
+
+	if !tsb.readDeadline.IsZero() && !time.Now().Before(tsb.readDeadline) {
+		return nil, &BuffErr{Op: "peek", Err: fmt.Errorf("%w", os.ErrDeadlineExceeded)}
+	}
+
+	select {
+	case <-tsb.done:
+		if tsb.shouldDrain() {
+			if tsb.buffer.Len() >= n {
+				result, aliased := tsb.buffer.Peek(n, tsb.peekBuf)
+
+				// never keep an aliased result as scratch: it's still backed
+				// by a live chunk, and reusing it on a later cross-chunk Peek
+				// would overwrite not-yet-read data in place
+				if !aliased {
+					tsb.peekBuf = result
+				}
+
+				// Peek doesn't consume anything, so re-notify for the next
+				// Read/Peek to see the same data
+				tsb.notify.Add()
+
+				return result, nil
+			}
+
+			return nil, &BuffErr{Op: "peek", Err: fmt.Errorf("%w. Attempting to peek more data than is in the buffer", ErrClosed)}
+		}
+
+		return nil, &BuffErr{Op: "peek", Err: fmt.Errorf("%w", ErrClosed)}
+	default:
+		if tsb.buffer.Len() >= n {
+			result, aliased := tsb.buffer.Peek(n, tsb.peekBuf)
+
+			// never keep an aliased result as scratch: it's still backed by
+			// a live chunk, and reusing it on a later cross-chunk Peek would
+			// overwrite not-yet-read data in place
+			if !aliased {
+				tsb.peekBuf = result
+			}
+
+			// Peek doesn't consume anything, so re-notify for the next
+			// Read/Peek to see the same data
+			tsb.notify.Add()
+
+			return result, nil
+		}
+
+		return nil, nil
+	}
+}
+
+// Available returns the number of bytes currently buffered and available to
+// Read or Peek.
+func (tsb *ThreadSafeBuffer) Available() int {
+	tsb.bufferLock.Lock()
+	defer tsb.bufferLock.Unlock()
+
+	return tsb.buffer.Len()
+}
+
+// Discard drops the next n buffered bytes, typically after a successful
+// Peek, without ever copying them. It returns the number of bytes actually
+// discarded, which is less than n (along with an error) if fewer than n
+// bytes were buffered.
+func (tsb *ThreadSafeBuffer) Discard(n int) (int, error) {
+	if n <= 0 {
+		return 0, nil
+	}
+
+	tsb.bufferLock.Lock()
+	defer tsb.bufferLock.Unlock()
+
+	available := tsb.buffer.Len()
+
+	discarded := tsb.buffer.Discard(n)
+	if discarded > 0 {
+		// space just freed up, wake any writers blocked on a full buffer
+		tsb.writeNotify.Add()
+	}
+
+	if discarded < n {
+		return discarded, &BuffErr{Op: "discard", Err: fmt.Errorf("buffer only had %d bytes buffered, discarded %d of %d requested", available, discarded, n)}
+	}
+
+	return discarded, nil
+}
+
 func (tsb *ThreadSafeBuffer) shouldDrain() bool {
 	if tsb.config.DrainRead {
 		if tsb.config.DrainTime != 0 && time.Since(tsb.drainTime) > tsb.config.DrainTime {
@@ -184,7 +463,15 @@ func (tsb *ThreadSafeBuffer) Close() {
 		tsb.bufferLock.Lock()
 		defer tsb.bufferLock.Unlock()
 
+		if tsb.readTimer != nil {
+			tsb.readTimer.Stop()
+		}
+		if tsb.writeTimer != nil {
+			tsb.writeTimer.Stop()
+		}
+
 		tsb.notify.Stop()
+		tsb.writeNotify.Stop()
 		close(tsb.done)
 
 		if tsb.config.DrainTime != 0 {
@@ -192,3 +479,87 @@ func (tsb *ThreadSafeBuffer) Close() {
 		}
 	})
 }
+
+// SetDeadline sets both the read and write deadlines, matching the
+// semantics of net.Conn.SetDeadline. A zero value for t cancels any
+// currently set deadline. A deadline in the past causes any in-flight
+// and future Read/Write to fail immediately.
+func (tsb *ThreadSafeBuffer) SetDeadline(t time.Time) error {
+	if err := tsb.SetReadDeadline(t); err != nil {
+		return err
+	}
+
+	return tsb.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for future Read calls and any
+// currently blocked Read call, matching the semantics of
+// net.Conn.SetReadDeadline. A zero value for t cancels any currently set
+// deadline.
+func (tsb *ThreadSafeBuffer) SetReadDeadline(t time.Time) error {
+	tsb.bufferLock.Lock()
+	defer tsb.bufferLock.Unlock()
+
+	select {
+	case <-tsb.done:
+		return &BuffErr{Op: "setReadDeadline", Err: fmt.Errorf("%w", ErrClosed)}
+	default:
+	}
+
+	tsb.readDeadline = t
+	if tsb.readTimer != nil {
+		tsb.readTimer.Stop()
+		tsb.readTimer = nil
+	}
+
+	if t.IsZero() {
+		return nil
+	}
+
+	if duration := time.Until(t); duration > 0 {
+		tsb.readTimer = time.AfterFunc(duration, func() {
+			tsb.notify.Add()
+		})
+	} else {
+		// deadline has already passed, wake up any blocked Read immediately
+		tsb.notify.Add()
+	}
+
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for future Write calls and any
+// currently blocked Write call, matching the semantics of
+// net.Conn.SetWriteDeadline. A zero value for t cancels any currently set
+// deadline.
+func (tsb *ThreadSafeBuffer) SetWriteDeadline(t time.Time) error {
+	tsb.bufferLock.Lock()
+	defer tsb.bufferLock.Unlock()
+
+	select {
+	case <-tsb.done:
+		return &BuffErr{Op: "setWriteDeadline", Err: fmt.Errorf("%w", ErrClosed)}
+	default:
+	}
+
+	tsb.writeDeadline = t
+	if tsb.writeTimer != nil {
+		tsb.writeTimer.Stop()
+		tsb.writeTimer = nil
+	}
+
+	if t.IsZero() {
+		return nil
+	}
+
+	if duration := time.Until(t); duration > 0 {
+		tsb.writeTimer = time.AfterFunc(duration, func() {
+			tsb.writeNotify.Add()
+		})
+	} else {
+		// deadline has already passed, wake up any blocked Write immediately
+		tsb.writeNotify.Add()
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package gothreadsafebuffer_test
 
 import (
+	"context"
+	"errors"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -199,6 +202,356 @@ func Test_Read(t *testing.T) {
 	})
 }
 
+func Test_Deadlines(t *testing.T) {
+	g := NewGomegaWithT(t)
+	unlimitedConfig := gothreadsafebuffer.UnlimitedBuffer()
+
+	t.Run("SetReadDeadline", func(t *testing.T) {
+		t.Run("causes a blocked Read to fail once the deadline passes", func(t *testing.T) {
+			t.Parallel()
+			threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+			defer threadSafeBuffer.Close()
+
+			err := threadSafeBuffer.SetReadDeadline(time.Now().Add(time.Millisecond))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			buffer := make([]byte, 5)
+			n, err := threadSafeBuffer.Read(buffer)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(errors.Is(err, os.ErrDeadlineExceeded)).To(BeTrue())
+			g.Expect(n).To(Equal(0))
+		})
+
+		t.Run("a zero value clears a previously set deadline", func(t *testing.T) {
+			t.Parallel()
+			threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+			defer threadSafeBuffer.Close()
+
+			g.Expect(threadSafeBuffer.SetReadDeadline(time.Now().Add(time.Millisecond))).ToNot(HaveOccurred())
+			g.Expect(threadSafeBuffer.SetReadDeadline(time.Time{})).ToNot(HaveOccurred())
+
+			_, err := threadSafeBuffer.Write([]byte(`hello`))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			buffer := make([]byte, 5)
+			n, err := threadSafeBuffer.Read(buffer)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(n).To(Equal(5))
+		})
+	})
+
+	t.Run("SetWriteDeadline", func(t *testing.T) {
+		t.Run("causes a Write to fail once the deadline has already passed", func(t *testing.T) {
+			t.Parallel()
+			threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+			defer threadSafeBuffer.Close()
+
+			err := threadSafeBuffer.SetWriteDeadline(time.Now().Add(-time.Second))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			n, err := threadSafeBuffer.Write([]byte(`hello`))
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(errors.Is(err, os.ErrDeadlineExceeded)).To(BeTrue())
+			g.Expect(n).To(Equal(0))
+		})
+	})
+
+	t.Run("SetDeadline", func(t *testing.T) {
+		t.Run("returns an error once the buffer is closed", func(t *testing.T) {
+			t.Parallel()
+			threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+			threadSafeBuffer.Close()
+
+			err := threadSafeBuffer.SetDeadline(time.Now().Add(time.Second))
+			g.Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func Test_ReadContext(t *testing.T) {
+	g := NewGomegaWithT(t)
+	unlimitedConfig := gothreadsafebuffer.UnlimitedBuffer()
+
+	t.Run("returns ctx.Err() when the context is canceled before any data arrives", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+		defer threadSafeBuffer.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		buffer := make([]byte, 5)
+		n, err := threadSafeBuffer.ReadContext(ctx, buffer)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		g.Expect(n).To(Equal(0))
+	})
+
+	t.Run("unblocks a pending read without closing the buffer", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+		defer threadSafeBuffer.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		buffer := make([]byte, 5)
+		n, err := threadSafeBuffer.ReadContext(ctx, buffer)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		g.Expect(n).To(Equal(0))
+
+		// buffer is still usable after the context-scoped read was canceled
+		_, err = threadSafeBuffer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		buffer = make([]byte, 5)
+		n, err = threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+	})
+}
+
+func Test_WriteContext(t *testing.T) {
+	g := NewGomegaWithT(t)
+	unlimitedConfig := gothreadsafebuffer.UnlimitedBuffer()
+
+	t.Run("returns ctx.Err() when the context is already canceled", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+		defer threadSafeBuffer.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		n, err := threadSafeBuffer.WriteContext(ctx, []byte(`hello`))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		g.Expect(n).To(Equal(0))
+	})
+}
+
+func Test_BlockOnFull(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Write blocks until a Read frees up enough room, then completes", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(gothreadsafebuffer.Config{
+			MaxBuffer:     true,
+			MaxBufferSize: 5,
+			BlockOnFull:   true,
+		})
+		defer threadSafeBuffer.Close()
+
+		n, err := threadSafeBuffer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+
+		writeDone := make(chan struct{})
+		go func() {
+			defer close(writeDone)
+			n, err := threadSafeBuffer.Write([]byte(`world`))
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(n).To(Equal(5))
+		}()
+
+		// give the blocked write time to actually block before freeing space
+		time.Sleep(10 * time.Millisecond)
+
+		buffer := make([]byte, 5)
+		n, err = threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+		g.Expect(buffer).To(Equal([]byte(`hello`)))
+
+		select {
+		case <-writeDone:
+		case <-time.After(time.Second):
+			t.Fatal("blocked write never completed after space was freed")
+		}
+
+		buffer = make([]byte, 5)
+		n, err = threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+		g.Expect(buffer).To(Equal([]byte(`world`)))
+	})
+
+	t.Run("a blocked Write unblocks with an error when the buffer is closed", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(gothreadsafebuffer.Config{
+			MaxBuffer:     true,
+			MaxBufferSize: 0,
+			BlockOnFull:   true,
+		})
+
+		writeDone := make(chan struct{})
+		go func() {
+			defer close(writeDone)
+			n, err := threadSafeBuffer.Write([]byte(`hello`))
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(n).To(Equal(0))
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		threadSafeBuffer.Close()
+
+		select {
+		case <-writeDone:
+		case <-time.After(time.Second):
+			t.Fatal("blocked write never unblocked after Close")
+		}
+	})
+
+	t.Run("a blocked Write unblocks with an error once Config.WriteTimeout elapses", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(gothreadsafebuffer.Config{
+			MaxBuffer:     true,
+			MaxBufferSize: 5,
+			BlockOnFull:   true,
+			WriteTimeout:  10 * time.Millisecond,
+		})
+		defer threadSafeBuffer.Close()
+
+		n, err := threadSafeBuffer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+
+		n, err = threadSafeBuffer.Write([]byte(`world`))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(n).To(Equal(0))
+
+		// buffer is still usable after the timed-out write
+		buffer := make([]byte, 5)
+		n, err = threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+		g.Expect(buffer).To(Equal([]byte(`hello`)))
+	})
+}
+
+func Test_Peek(t *testing.T) {
+	g := NewGomegaWithT(t)
+	unlimitedConfig := gothreadsafebuffer.UnlimitedBuffer()
+
+	t.Run("returns buffered data without consuming it", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+		defer threadSafeBuffer.Close()
+
+		_, err := threadSafeBuffer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		peeked, err := threadSafeBuffer.Peek(5)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(peeked).To(Equal([]byte(`hello`)))
+		g.Expect(threadSafeBuffer.Available()).To(Equal(5))
+
+		buffer := make([]byte, 5)
+		n, err := threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+		g.Expect(buffer).To(Equal([]byte(`hello`)))
+	})
+
+	t.Run("blocks until enough data has been written", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+		defer threadSafeBuffer.Close()
+
+		peekDone := make(chan []byte)
+		go func() {
+			peeked, err := threadSafeBuffer.Peek(5)
+			g.Expect(err).ToNot(HaveOccurred())
+			peekDone <- peeked
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		_, err := threadSafeBuffer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		select {
+		case peeked := <-peekDone:
+			g.Expect(peeked).To(Equal([]byte(`hello`)))
+		case <-time.After(time.Second):
+			t.Fatal("Peek never returned after enough data was written")
+		}
+	})
+
+	t.Run("does not corrupt unread data when a single-chunk Peek is followed by a cross-chunk Peek", func(t *testing.T) {
+		t.Parallel()
+		config := gothreadsafebuffer.UnlimitedBuffer()
+		config.ChunkSize = 4
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(config)
+		defer threadSafeBuffer.Close()
+
+		_, err := threadSafeBuffer.Write([]byte(`abcd`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		// aliases chunk 0's backing array directly
+		peeked, err := threadSafeBuffer.Peek(4)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(peeked).To(Equal([]byte(`abcd`)))
+
+		// consumes "ab", leaving "cd" unread in the same chunk
+		buffer := make([]byte, 2)
+		_, err = threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(buffer).To(Equal([]byte(`ab`)))
+
+		_, err = threadSafeBuffer.Write([]byte(`ef`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		// spans the chunk boundary; must not clobber the unread "cd"
+		peeked, err = threadSafeBuffer.Peek(4)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(peeked).To(Equal([]byte(`cdef`)))
+
+		buffer = make([]byte, 4)
+		n, err := threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(4))
+		g.Expect(buffer).To(Equal([]byte(`cdef`)))
+	})
+}
+
+func Test_Discard(t *testing.T) {
+	g := NewGomegaWithT(t)
+	unlimitedConfig := gothreadsafebuffer.UnlimitedBuffer()
+
+	t.Run("drops the requested number of buffered bytes", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+		defer threadSafeBuffer.Close()
+
+		_, err := threadSafeBuffer.Write([]byte(`hello world`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		n, err := threadSafeBuffer.Discard(6)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(6))
+
+		buffer := make([]byte, 5)
+		n, err = threadSafeBuffer.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+		g.Expect(buffer).To(Equal([]byte(`world`)))
+	})
+
+	t.Run("returns an error and the short count if fewer bytes are buffered than requested", func(t *testing.T) {
+		t.Parallel()
+		threadSafeBuffer := gothreadsafebuffer.NewThreadSafeBuffer(unlimitedConfig)
+		defer threadSafeBuffer.Close()
+
+		_, err := threadSafeBuffer.Write([]byte(`hi`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		n, err := threadSafeBuffer.Discard(5)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(n).To(Equal(2))
+	})
+}
+
 func Test_Parallelism(t *testing.T) {
 	t.Parallel()
 	g := NewGomegaWithT(t)
@@ -8,6 +8,11 @@ type Config struct {
 	// max size of the buffer. if this size was to be exceeded on writes, thrown an error instead
 	MaxBufferSize int
 
+	// When set to true, a Write that would exceed MaxBufferSize blocks until
+	// enough data has been Read to make room, instead of returning an error.
+	// Has no effect unless MaxBuffer is also true.
+	BlockOnFull bool
+
 	// after a Close() call if true. Will allow Read to be called untill the buffer is drained
 	DrainRead bool
 
@@ -15,9 +20,37 @@ type Config struct {
 	// To have this be infinite set this to 0
 	ReadTimeout time.Duration
 
+	// How long it should take for a write operation before reporting an error.
+	// To have this be infinite set this to 0
+	WriteTimeout time.Duration
+
 	// how long to wait for Read() operations to drain before just reporting errors
 	// To have this be infinite set this to 0
 	DrainTime time.Duration
+
+	// Size of each chunk in the buffer's segmented backing store. Writes fill
+	// a chunk before allocating the next one, and Reads release chunks back
+	// to BufferPool (if set) once fully consumed. Defaults to DefaultChunkSize
+	// if left at 0.
+	ChunkSize int
+
+	// Optional pool used to recycle the []byte chunks backing the buffer,
+	// reducing GC pressure under sustained throughput. If nil, chunks are
+	// allocated with make and left for the garbage collector.
+	BufferPool BufferPool
+}
+
+// DefaultChunkSize is used for Config.ChunkSize when it is left at 0.
+const DefaultChunkSize = 64 * 1024
+
+// BufferPool lets callers supply their own pool for the []byte chunks a
+// ThreadSafeBuffer uses for its backing store, e.g. a sync.Pool tuned to
+// Config.ChunkSize.
+type BufferPool interface {
+	// Get returns a []byte for reuse, or nil if none is available.
+	Get() []byte
+	// Put returns a []byte (with len reset to 0) to the pool for reuse.
+	Put([]byte)
 }
 
 func UnlimitedBuffer() Config {
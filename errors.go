@@ -1,6 +1,15 @@
 package gothreadsafebuffer
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is returned (wrapped in a BuffErr) when a Read or Write is
+// attempted against a buffer that has already been closed, or by the
+// deadline setters once the buffer is closed. Callers can check for it with
+// errors.Is.
+var ErrClosed = errors.New("Thread safe buffer is closed")
 
 type BuffErr struct {
 	Op  string
@@ -10,3 +19,9 @@ type BuffErr struct {
 func (be *BuffErr) Error() string {
 	return fmt.Sprintf("Failed buffer %s: %s", be.Op, be.Err.Error())
 }
+
+// Unwrap allows errors.Is/errors.As to see through to the wrapped Err, so
+// callers can check for sentinel errors such as os.ErrDeadlineExceeded.
+func (be *BuffErr) Unwrap() error {
+	return be.Err
+}
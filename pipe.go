@@ -0,0 +1,239 @@
+package gothreadsafebuffer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// onceError is an error that can be set once, and is safe for concurrent
+// use. Mirrors the same primitive io.Pipe uses internally.
+type onceError struct {
+	lock sync.Mutex
+	err  error
+}
+
+// Store records err if no error has been stored yet. Subsequent calls are
+// no-ops.
+func (oe *onceError) Store(err error) {
+	oe.lock.Lock()
+	defer oe.lock.Unlock()
+
+	if oe.err != nil || err == nil {
+		return
+	}
+	oe.err = err
+}
+
+// Load returns the stored error, or nil if none has been stored.
+func (oe *onceError) Load() error {
+	oe.lock.Lock()
+	defer oe.lock.Unlock()
+
+	return oe.err
+}
+
+// BufferReader is the read half of a Pipe.
+type BufferReader struct {
+	buffer *ThreadSafeBuffer
+
+	rerr *onceError
+	werr *onceError
+}
+
+// BufferWriter is the write half of a Pipe.
+type BufferWriter struct {
+	buffer *ThreadSafeBuffer
+
+	rerr *onceError
+	werr *onceError
+}
+
+// Pipe creates a buffered, thread safe, in-memory pipe backed by a
+// ThreadSafeBuffer configured with config. It can be used to connect code
+// expecting an io.Reader with code expecting an io.Writer, the same way
+// io.Pipe does, but with the buffering, blocking-write, drain, and deadline
+// behavior this module already provides.
+func Pipe(config Config) (*BufferReader, *BufferWriter) {
+	// a pipe must always deliver any data already written before surfacing
+	// the close error, the same way io.Pipe does
+	config.DrainRead = true
+
+	buffer := NewThreadSafeBuffer(config)
+
+	rerr := new(onceError)
+	werr := new(onceError)
+
+	return &BufferReader{buffer: buffer, rerr: rerr, werr: werr},
+		&BufferWriter{buffer: buffer, rerr: rerr, werr: werr}
+}
+
+// Read implements io.Reader. Once the writer half has been closed, Read
+// drains any remaining buffered data and then returns the writer's stored
+// close error (io.EOF by default). Like ThreadSafeBuffer.Read, it only
+// returns data once len(b) bytes can be filled: after the writer closes, a
+// Read larger than what remains buffered returns the close error with n == 0
+// while that data is still buffered.
+func (br *BufferReader) Read(b []byte) (int, error) {
+	n, err := br.buffer.Read(b)
+	if err != nil && errors.Is(err, ErrClosed) {
+		return n, br.readCloseError()
+	}
+
+	return n, err
+}
+
+// ReadContext behaves like Read, but also returns ctx.Err() if ctx is
+// canceled before the read completes.
+func (br *BufferReader) ReadContext(ctx context.Context, b []byte) (int, error) {
+	n, err := br.buffer.ReadContext(ctx, b)
+	if err != nil && errors.Is(err, ErrClosed) {
+		return n, br.readCloseError()
+	}
+
+	return n, err
+}
+
+// Close closes the reader, equivalent to CloseWithError(nil). Future writes
+// fail with io.ErrClosedPipe.
+func (br *BufferReader) Close() error {
+	return br.CloseWithError(nil)
+}
+
+// CloseWithError closes the reader. Future writes fail with err, or
+// io.ErrClosedPipe if err is nil. CloseWithError never overwrites a prior
+// close of this half.
+func (br *BufferReader) CloseWithError(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	br.rerr.Store(err)
+	br.buffer.Close()
+
+	return nil
+}
+
+// readCloseError reports the error a Read should return once the buffer is
+// closed and drained: the writer's close error if only the writer closed,
+// otherwise io.ErrClosedPipe.
+func (br *BufferReader) readCloseError() error {
+	if werr := br.werr.Load(); werr != nil && br.rerr.Load() == nil {
+		return werr
+	}
+
+	return io.ErrClosedPipe
+}
+
+// Peek returns the next n buffered bytes without consuming them, blocking
+// until that much data has been written. See ThreadSafeBuffer.Peek. Once the
+// writer half has been closed, a Peek for more bytes than remain buffered
+// returns the writer's stored close error (io.EOF by default), the same way
+// Read does.
+func (br *BufferReader) Peek(n int) ([]byte, error) {
+	b, err := br.buffer.Peek(n)
+	if err != nil && errors.Is(err, ErrClosed) {
+		return b, br.readCloseError()
+	}
+
+	return b, err
+}
+
+// PeekContext behaves like Peek, but also returns ctx.Err() if ctx is
+// canceled before enough data is available.
+func (br *BufferReader) PeekContext(ctx context.Context, n int) ([]byte, error) {
+	b, err := br.buffer.PeekContext(ctx, n)
+	if err != nil && errors.Is(err, ErrClosed) {
+		return b, br.readCloseError()
+	}
+
+	return b, err
+}
+
+// Available returns the number of bytes currently buffered and available to
+// Read or Peek.
+func (br *BufferReader) Available() int {
+	return br.buffer.Available()
+}
+
+// Discard drops the next n buffered bytes, typically after a successful
+// Peek. See ThreadSafeBuffer.Discard.
+func (br *BufferReader) Discard(n int) (int, error) {
+	return br.buffer.Discard(n)
+}
+
+// SetReadDeadline sets the deadline for future Read/Peek calls and any
+// currently blocked one, matching the semantics of
+// net.Conn.SetReadDeadline. A zero value for t cancels any currently set
+// deadline.
+func (br *BufferReader) SetReadDeadline(t time.Time) error {
+	return br.buffer.SetReadDeadline(t)
+}
+
+// Write implements io.Writer.
+func (bw *BufferWriter) Write(b []byte) (int, error) {
+	if err := bw.rerr.Load(); err != nil {
+		return 0, err
+	}
+
+	n, err := bw.buffer.Write(b)
+	if err != nil && errors.Is(err, ErrClosed) {
+		return n, bw.writeCloseError()
+	}
+
+	return n, err
+}
+
+// WriteContext behaves like Write, but also returns ctx.Err() if ctx is
+// canceled before the write completes.
+func (bw *BufferWriter) WriteContext(ctx context.Context, b []byte) (int, error) {
+	if err := bw.rerr.Load(); err != nil {
+		return 0, err
+	}
+
+	n, err := bw.buffer.WriteContext(ctx, b)
+	if err != nil && errors.Is(err, ErrClosed) {
+		return n, bw.writeCloseError()
+	}
+
+	return n, err
+}
+
+// Close closes the writer, equivalent to CloseWithError(nil). Reads drain
+// any remaining data and then return io.EOF.
+func (bw *BufferWriter) Close() error {
+	return bw.CloseWithError(nil)
+}
+
+// CloseWithError closes the writer. Reads drain any remaining data and then
+// return err, or io.EOF if err is nil. CloseWithError never overwrites a
+// prior close of this half.
+func (bw *BufferWriter) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	bw.werr.Store(err)
+	bw.buffer.Close()
+
+	return nil
+}
+
+// writeCloseError reports the error a Write should return once the buffer
+// is closed: the reader's close error if only the reader closed, otherwise
+// io.ErrClosedPipe.
+func (bw *BufferWriter) writeCloseError() error {
+	if rerr := bw.rerr.Load(); rerr != nil && bw.werr.Load() == nil {
+		return rerr
+	}
+
+	return io.ErrClosedPipe
+}
+
+// SetWriteDeadline sets the deadline for future Write calls and any
+// currently blocked Write call, matching the semantics of
+// net.Conn.SetWriteDeadline. A zero value for t cancels any currently set
+// deadline.
+func (bw *BufferWriter) SetWriteDeadline(t time.Time) error {
+	return bw.buffer.SetWriteDeadline(t)
+}
@@ -0,0 +1,174 @@
+package gothreadsafebuffer_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DanLavine/gothreadsafebuffer"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Pipe(t *testing.T) {
+	g := NewGomegaWithT(t)
+	unlimitedConfig := gothreadsafebuffer.UnlimitedBuffer()
+
+	t.Run("data written to the writer half can be read from the reader half", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer reader.Close()
+		defer writer.Close()
+
+		n, err := writer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+
+		buffer := make([]byte, 5)
+		n, err = reader.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+		g.Expect(buffer).To(Equal([]byte(`hello`)))
+	})
+
+	t.Run("closing the writer drains remaining data then returns io.EOF", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer reader.Close()
+
+		_, err := writer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(writer.Close()).ToNot(HaveOccurred())
+
+		buffer := make([]byte, 5)
+		n, err := reader.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+
+		n, err = reader.Read(buffer)
+		g.Expect(err).To(Equal(io.EOF))
+		g.Expect(n).To(Equal(0))
+	})
+
+	t.Run("closing the writer returns io.EOF from an oversized Read, not the internal drain error", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer reader.Close()
+
+		_, err := writer.Write([]byte(`hi`))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(writer.Close()).ToNot(HaveOccurred())
+
+		// the scratch buffer is bigger than what's left, the common
+		// io.Copy-style shape
+		buffer := make([]byte, 10)
+		n, err := reader.Read(buffer)
+		g.Expect(err).To(Equal(io.EOF))
+		g.Expect(n).To(Equal(0))
+	})
+
+	t.Run("closing the writer before anything is written returns io.EOF from Peek, not the internal drain error", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer reader.Close()
+
+		g.Expect(writer.Close()).ToNot(HaveOccurred())
+
+		_, err := reader.Peek(1)
+		g.Expect(err).To(Equal(io.EOF))
+	})
+
+	t.Run("closing the writer with an error surfaces that error from Read after draining", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer reader.Close()
+
+		boom := io.ErrUnexpectedEOF
+		g.Expect(writer.CloseWithError(boom)).ToNot(HaveOccurred())
+
+		buffer := make([]byte, 5)
+		n, err := reader.Read(buffer)
+		g.Expect(err).To(Equal(boom))
+		g.Expect(n).To(Equal(0))
+	})
+
+	t.Run("closing the reader causes a subsequent write to fail with io.ErrClosedPipe", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer writer.Close()
+
+		g.Expect(reader.Close()).ToNot(HaveOccurred())
+
+		n, err := writer.Write([]byte(`hello`))
+		g.Expect(err).To(Equal(io.ErrClosedPipe))
+		g.Expect(n).To(Equal(0))
+	})
+
+	t.Run("closing the reader with an error surfaces that error from Write", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer writer.Close()
+
+		boom := io.ErrUnexpectedEOF
+		g.Expect(reader.CloseWithError(boom)).ToNot(HaveOccurred())
+
+		n, err := writer.Write([]byte(`hello`))
+		g.Expect(err).To(Equal(boom))
+		g.Expect(n).To(Equal(0))
+	})
+
+	t.Run("the reader half can Peek, Discard, and Available the underlying buffer", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer reader.Close()
+		defer writer.Close()
+
+		_, err := writer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		peeked, err := reader.Peek(5)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(peeked).To(Equal([]byte(`hello`)))
+		g.Expect(reader.Available()).To(Equal(5))
+
+		n, err := reader.Discard(5)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(5))
+		g.Expect(reader.Available()).To(Equal(0))
+	})
+
+	t.Run("SetReadDeadline fails a pending Read on the reader half", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(unlimitedConfig)
+		defer reader.Close()
+		defer writer.Close()
+
+		g.Expect(reader.SetReadDeadline(time.Now().Add(10 * time.Millisecond))).ToNot(HaveOccurred())
+
+		buffer := make([]byte, 5)
+		_, err := reader.Read(buffer)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, os.ErrDeadlineExceeded)).To(BeTrue())
+	})
+
+	t.Run("SetWriteDeadline fails a pending blocking Write on the writer half", func(t *testing.T) {
+		t.Parallel()
+		reader, writer := gothreadsafebuffer.Pipe(gothreadsafebuffer.Config{
+			MaxBuffer:     true,
+			MaxBufferSize: 5,
+			BlockOnFull:   true,
+		})
+		defer reader.Close()
+		defer writer.Close()
+
+		_, err := writer.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(writer.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))).ToNot(HaveOccurred())
+
+		_, err = writer.Write([]byte(`world`))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, os.ErrDeadlineExceeded)).To(BeTrue())
+	})
+}
@@ -0,0 +1,205 @@
+package gothreadsafebuffer
+
+import "io"
+
+// segmentedChunk is a single fixed-capacity []byte in a segmentedBuffer,
+// along with how far it has been read.
+type segmentedChunk struct {
+	data []byte
+	read int
+
+	// peeked is set once this chunk's backing array has been returned
+	// directly (aliased) from Peek. Such a chunk must never be recycled
+	// through the pool: a caller may still be holding that alias, and
+	// handing the same backing array to a later Write would silently
+	// overwrite it.
+	peeked bool
+}
+
+// segmentedBuffer is a queue of fixed-size []byte chunks used as the
+// ThreadSafeBuffer backing store, in place of a single bytes.Buffer.
+// Writes fill the tail chunk and allocate a new one once it is full; Reads
+// drain the head chunk and drop it (returning it to the pool, if any) once
+// fully consumed. This avoids the unbounded grow-and-never-shrink behavior
+// of bytes.Buffer under bursty producers followed by slow consumers, and
+// keeps Len() cheap to enforce MaxBufferSize without ever copying a large
+// contiguous region.
+type segmentedBuffer struct {
+	chunkSize int
+	pool      BufferPool
+
+	chunks []*segmentedChunk
+}
+
+// newSegmentedBuffer creates a segmentedBuffer that allocates chunkSize
+// ([]byte) chunks, falling back to DefaultChunkSize if chunkSize <= 0. If
+// pool is non-nil, it is used to get and put the chunks' backing arrays.
+func newSegmentedBuffer(chunkSize int, pool BufferPool) *segmentedBuffer {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	return &segmentedBuffer{
+		chunkSize: chunkSize,
+		pool:      pool,
+	}
+}
+
+// getChunk returns a zero-length []byte with capacity chunkSize, reusing
+// one from the pool when possible.
+func (sb *segmentedBuffer) getChunk() []byte {
+	if sb.pool != nil {
+		if data := sb.pool.Get(); data != nil {
+			return data[:0]
+		}
+	}
+
+	return make([]byte, 0, sb.chunkSize)
+}
+
+// putChunk returns a drained chunk's backing array to the pool, if any,
+// unless it was ever exposed by an aliased Peek, in which case it is left
+// for the garbage collector instead of risking a caller's outstanding
+// reference being overwritten.
+func (sb *segmentedBuffer) putChunk(c *segmentedChunk) {
+	if sb.pool != nil && !c.peeked {
+		sb.pool.Put(c.data[:0])
+	}
+}
+
+// Write appends p into the tail chunk, allocating new chunks as needed. It
+// always writes all of p.
+func (sb *segmentedBuffer) Write(p []byte) (int, error) {
+	written := 0
+
+	for written < len(p) {
+		if len(sb.chunks) == 0 {
+			sb.chunks = append(sb.chunks, &segmentedChunk{data: sb.getChunk()})
+		}
+
+		tail := sb.chunks[len(sb.chunks)-1]
+		if len(tail.data) == cap(tail.data) {
+			tail = &segmentedChunk{data: sb.getChunk()}
+			sb.chunks = append(sb.chunks, tail)
+		}
+
+		space := cap(tail.data) - len(tail.data)
+		n := len(p) - written
+		if n > space {
+			n = space
+		}
+
+		tail.data = append(tail.data, p[written:written+n]...)
+		written += n
+	}
+
+	return written, nil
+}
+
+// Read copies from the head chunk(s) into p, dropping chunks whose read
+// cursor has reached their length. Returns io.EOF if there is nothing
+// buffered.
+func (sb *segmentedBuffer) Read(p []byte) (int, error) {
+	read := 0
+
+	for read < len(p) && len(sb.chunks) > 0 {
+		head := sb.chunks[0]
+
+		n := copy(p[read:], head.data[head.read:])
+		head.read += n
+		read += n
+
+		if head.read == len(head.data) {
+			sb.putChunk(head)
+			sb.chunks = sb.chunks[1:]
+		}
+	}
+
+	if read == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+
+	return read, nil
+}
+
+// Peek returns a []byte of length n referencing buffered data without
+// consuming it, along with whether that []byte aliases a live chunk's
+// backing array. If n fits within the head chunk, the returned slice
+// aliases that chunk directly and aliased is true. Otherwise the data spans
+// more than one chunk and is copied into scratch (growing it if necessary)
+// so the caller gets a contiguous slice, and aliased is false; the returned
+// []byte may therefore be scratch itself.
+//
+// Callers must have already checked Len() >= n. Callers must not pass a
+// scratch they got back with aliased == true into a later Peek call: it is
+// still backed by a live chunk, and a later cross-chunk Peek copying into it
+// would overwrite not-yet-read data in place.
+func (sb *segmentedBuffer) Peek(n int, scratch []byte) (result []byte, aliased bool) {
+	if len(sb.chunks) > 0 {
+		head := sb.chunks[0]
+		if len(head.data)-head.read >= n {
+			head.peeked = true
+			return head.data[head.read : head.read+n], true
+		}
+	}
+
+	if cap(scratch) < n {
+		scratch = make([]byte, n)
+	}
+	scratch = scratch[:n]
+
+	copied := 0
+	for _, c := range sb.chunks {
+		if copied >= n {
+			break
+		}
+
+		avail := len(c.data) - c.read
+		take := n - copied
+		if take > avail {
+			take = avail
+		}
+
+		copy(scratch[copied:copied+take], c.data[c.read:c.read+take])
+		copied += take
+	}
+
+	return scratch, false
+}
+
+// Discard drops up to n buffered bytes without copying them anywhere,
+// releasing any chunks that become fully consumed. Returns the number of
+// bytes actually discarded, which is less than n if fewer than n bytes were
+// buffered.
+func (sb *segmentedBuffer) Discard(n int) int {
+	discarded := 0
+
+	for discarded < n && len(sb.chunks) > 0 {
+		head := sb.chunks[0]
+		avail := len(head.data) - head.read
+		take := n - discarded
+		if take > avail {
+			take = avail
+		}
+
+		head.read += take
+		discarded += take
+
+		if head.read == len(head.data) {
+			sb.putChunk(head)
+			sb.chunks = sb.chunks[1:]
+		}
+	}
+
+	return discarded
+}
+
+// Len returns the number of unread bytes currently buffered.
+func (sb *segmentedBuffer) Len() int {
+	length := 0
+	for _, c := range sb.chunks {
+		length += len(c.data) - c.read
+	}
+
+	return length
+}
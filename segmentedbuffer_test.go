@@ -0,0 +1,90 @@
+package gothreadsafebuffer
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_segmentedBuffer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("allocates a new chunk once the tail chunk is full", func(t *testing.T) {
+		sb := newSegmentedBuffer(4, nil)
+
+		_, err := sb.Write([]byte(`hello`))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(sb.Len()).To(Equal(5))
+		g.Expect(sb.chunks).To(HaveLen(2))
+	})
+
+	t.Run("Read drains across chunk boundaries and drops exhausted chunks", func(t *testing.T) {
+		sb := newSegmentedBuffer(4, nil)
+
+		_, err := sb.Write([]byte(`hello world`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		buffer := make([]byte, 11)
+		n, err := sb.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(11))
+		g.Expect(buffer).To(Equal([]byte(`hello world`)))
+		g.Expect(sb.Len()).To(Equal(0))
+		g.Expect(sb.chunks).To(HaveLen(0))
+	})
+
+	t.Run("falls back to DefaultChunkSize when chunkSize <= 0", func(t *testing.T) {
+		sb := newSegmentedBuffer(0, nil)
+		g.Expect(sb.chunkSize).To(Equal(DefaultChunkSize))
+	})
+
+	t.Run("recycles drained chunks through the configured BufferPool", func(t *testing.T) {
+		pool := &recordingPool{}
+		sb := newSegmentedBuffer(4, pool)
+
+		_, err := sb.Write([]byte(`data`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		buffer := make([]byte, 4)
+		_, err = sb.Read(buffer)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(pool.puts).To(Equal(1))
+	})
+
+	t.Run("does not recycle a chunk still referenced by an outstanding aliased Peek", func(t *testing.T) {
+		pool := &recordingPool{}
+		sb := newSegmentedBuffer(4, pool)
+
+		_, err := sb.Write([]byte(`abcd`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		peeked, aliased := sb.Peek(4, nil)
+		g.Expect(aliased).To(BeTrue())
+		g.Expect(peeked).To(Equal([]byte(`abcd`)))
+
+		discarded := sb.Discard(4)
+		g.Expect(discarded).To(Equal(4))
+		g.Expect(pool.puts).To(Equal(0))
+
+		// the recycled-looking chunk must not be the same backing array
+		// still referenced by peeked
+		_, err = sb.Write([]byte(`WXYZ`))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(peeked).To(Equal([]byte(`abcd`)))
+	})
+}
+
+// recordingPool is a minimal BufferPool used to verify chunks are returned
+// for reuse.
+type recordingPool struct {
+	puts int
+}
+
+func (rp *recordingPool) Get() []byte {
+	return nil
+}
+
+func (rp *recordingPool) Put(b []byte) {
+	rp.puts++
+}